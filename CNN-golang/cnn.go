@@ -2,26 +2,72 @@ package main
 
 import (
 	"fmt"
-	"image"
-	"image/color"
-	_ "image/jpeg"
-	_ "image/png"
 	"math"
 	"math/rand"
-	"os"
 	"time"
+
+	"github.com/OBigVee/Go-DL/imageprep"
 )
 
+// Param is a single trainable scalar exposed to an Optimizer: Value is the
+// weight itself and Grad is the accumulated gradient computed by Backward.
+type Param struct {
+	Value *float64
+	Grad  *float64
+}
+
+// Layer is implemented by every layer in the zoo (ConvLayer, MaxPoolLayer,
+// FlattenLayer, DenseLayer, LRNLayer, ...) so a Sequential can chain them
+// without knowing their concrete tensor shapes. Forward and Backward take
+// and return `any` because neighboring layers can disagree on shape (e.g.
+// FlattenLayer turns a [][][]float64 into a []float64); each implementation
+// asserts the type it expects and panics like any other invalid type
+// assertion if the previous layer didn't produce it. Params returns the
+// layer's trainable parameters for an Optimizer to update, or nil for
+// layers with no weights (MaxPoolLayer, FlattenLayer, LRNLayer).
+type Layer interface {
+	Forward(input any) any
+	Backward(dOut any) any
+	Params() []*Param
+}
+
 // Convolutional Layer
 type ConvLayer struct {
 	Filters [][][][]float64 // [numFilters][inChannels][kernelH][kernelW]
 	Biases  []float64
 	Stride  int
 	Padding int
+
+	// Algorithm selects how Forward computes the correlation. The zero
+	// value, Auto, picks FFT for large kernels and Direct otherwise; see
+	// ConvLayer.Forward.
+	Algorithm ConvAlgorithm
+
+	filterGrads [][][][]float64 // same shape as Filters, accumulated by Backward
+	biasGrads   []float64
+
+	lastInput  [][][]float64 // unpadded input, cached for Backward
+	lastPadded [][][]float64
+	lastOutput [][][]float64 // post-ReLU output, cached for the ReLU mask
 }
 
-func (cl *ConvLayer) Forward(input [][][]float64) [][][]float64 {
-	numFilters := len(cl.Filters)
+func (cl *ConvLayer) Params() []*Param {
+	var params []*Param
+	for f := range cl.Filters {
+		for c := range cl.Filters[f] {
+			for y := range cl.Filters[f][c] {
+				for x := range cl.Filters[f][c][y] {
+					params = append(params, &Param{&cl.Filters[f][c][y][x], &cl.filterGrads[f][c][y][x]})
+				}
+			}
+		}
+		params = append(params, &Param{&cl.Biases[f], &cl.biasGrads[f]})
+	}
+	return params
+}
+
+func (cl *ConvLayer) Forward(in any) any {
+	input := in.([][][]float64)
 	inChannels := len(input)
 	kernelH := len(cl.Filters[0][0])
 	kernelW := len(cl.Filters[0][0][0])
@@ -30,8 +76,43 @@ func (cl *ConvLayer) Forward(input [][][]float64) [][][]float64 {
 	inputW := len(input[0][0])
 
 	// Calculate output dimensions
-	outH := (inputH + 2*cl.Padding - kernelH)/cl.Stride + 1
-	outW := (inputW + 2*cl.Padding - kernelW)/cl.Stride + 1
+	outH := (inputH+2*cl.Padding-kernelH)/cl.Stride + 1
+	outW := (inputW+2*cl.Padding-kernelW)/cl.Stride + 1
+
+	// Apply padding to each input channel
+	padded := make([][][]float64, inChannels)
+	for c := range padded {
+		padded[c] = pad2D(input[c], cl.Padding)
+	}
+
+	algo := cl.Algorithm
+	if algo == Auto {
+		algo = Direct
+		if kernelH*kernelW >= 25 {
+			algo = FFT
+		}
+	}
+
+	var output [][][]float64
+	if algo == FFT {
+		output = cl.convolveFFT(padded, outH, outW)
+	} else {
+		output = cl.convolveDirect(padded, outH, outW)
+	}
+
+	cl.lastInput = input
+	cl.lastPadded = padded
+	cl.lastOutput = output
+	return output
+}
+
+// convolveDirect is the naive six-nested-loop correlation: O(numFilters *
+// inChannels * outH * outW * kernelH * kernelW).
+func (cl *ConvLayer) convolveDirect(padded [][][]float64, outH, outW int) [][][]float64 {
+	numFilters := len(cl.Filters)
+	inChannels := len(padded)
+	kernelH := len(cl.Filters[0][0])
+	kernelW := len(cl.Filters[0][0][0])
 
 	output := make([][][]float64, numFilters)
 	for f := range output {
@@ -41,13 +122,6 @@ func (cl *ConvLayer) Forward(input [][][]float64) [][][]float64 {
 		}
 	}
 
-	// Apply padding to each input channel
-	padded := make([][][]float64, inChannels)
-	for c := range padded {
-		padded[c] = pad2D(input[c], cl.Padding)
-	}
-
-	// Perform convolution
 	for f := 0; f < numFilters; f++ {
 		for y := 0; y < outH; y++ {
 			for x := 0; x < outW; x++ {
@@ -68,13 +142,70 @@ func (cl *ConvLayer) Forward(input [][][]float64) [][][]float64 {
 	return output
 }
 
+// Backward takes the gradient of the loss w.r.t. the layer's output,
+// accumulates filterGrads/biasGrads, and returns the gradient w.r.t. the
+// (unpadded) input. Forward must be called first.
+func (cl *ConvLayer) Backward(dOutArg any) any {
+	dOut := dOutArg.([][][]float64)
+	numFilters := len(cl.Filters)
+	inChannels := len(cl.lastInput)
+	kernelH := len(cl.Filters[0][0])
+	kernelW := len(cl.Filters[0][0][0])
+	outH := len(dOut[0])
+	outW := len(dOut[0][0])
+
+	cl.filterGrads = zerosLike4D(cl.Filters)
+	cl.biasGrads = make([]float64, numFilters)
+
+	dPadded := make([][][]float64, inChannels)
+	for c := range dPadded {
+		dPadded[c] = make([][]float64, len(cl.lastPadded[c]))
+		for y := range dPadded[c] {
+			dPadded[c][y] = make([]float64, len(cl.lastPadded[c][y]))
+		}
+	}
+
+	for f := 0; f < numFilters; f++ {
+		for y := 0; y < outH; y++ {
+			for x := 0; x < outW; x++ {
+				if cl.lastOutput[f][y][x] <= 0 {
+					continue // ReLU gradient is zero past the activation
+				}
+				d := dOut[f][y][x]
+				cl.biasGrads[f] += d
+				yStart := y * cl.Stride
+				xStart := x * cl.Stride
+				for c := 0; c < inChannels; c++ {
+					for ky := 0; ky < kernelH; ky++ {
+						for kx := 0; kx < kernelW; kx++ {
+							cl.filterGrads[f][c][ky][kx] += d * cl.lastPadded[c][yStart+ky][xStart+kx]
+							dPadded[c][yStart+ky][xStart+kx] += d * cl.Filters[f][c][ky][kx]
+						}
+					}
+				}
+			}
+		}
+	}
+
+	dInput := make([][][]float64, inChannels)
+	for c := range dInput {
+		dInput[c] = unpad2D(dPadded[c], cl.Padding)
+	}
+	return dInput
+}
+
 // Max Pooling Layer
 type MaxPoolLayer struct {
 	PoolSize int
 	Stride   int
+
+	lastInput [][][]float64
 }
 
-func (mpl *MaxPoolLayer) Forward(input [][][]float64) [][][]float64 {
+func (mpl *MaxPoolLayer) Params() []*Param { return nil }
+
+func (mpl *MaxPoolLayer) Forward(in any) any {
+	input := in.([][][]float64)
 	channels := len(input)
 	h := len(input[0])
 	w := len(input[0][0])
@@ -108,13 +239,71 @@ func (mpl *MaxPoolLayer) Forward(input [][][]float64) [][][]float64 {
 			}
 		}
 	}
+
+	mpl.lastInput = input
 	return output
 }
 
+// Backward routes each upstream gradient to the single input position that
+// won the max in Forward; all other positions in the window get zero.
+func (mpl *MaxPoolLayer) Backward(dOutArg any) any {
+	dOut := dOutArg.([][][]float64)
+	channels := len(mpl.lastInput)
+	h := len(mpl.lastInput[0])
+	w := len(mpl.lastInput[0][0])
+	outH := len(dOut[0])
+	outW := len(dOut[0][0])
+
+	dInput := make([][][]float64, channels)
+	for c := range dInput {
+		dInput[c] = make([][]float64, h)
+		for y := range dInput[c] {
+			dInput[c][y] = make([]float64, w)
+		}
+	}
+
+	for c := 0; c < channels; c++ {
+		for y := 0; y < outH; y++ {
+			for x := 0; x < outW; x++ {
+				yStart := y * mpl.Stride
+				xStart := x * mpl.Stride
+				maxY, maxX := yStart, xStart
+				maxVal := mpl.lastInput[c][yStart][xStart]
+				for ky := 0; ky < mpl.PoolSize; ky++ {
+					for kx := 0; kx < mpl.PoolSize; kx++ {
+						val := mpl.lastInput[c][yStart+ky][xStart+kx]
+						if val > maxVal {
+							maxVal = val
+							maxY, maxX = yStart+ky, xStart+kx
+						}
+					}
+				}
+				dInput[c][maxY][maxX] += dOut[c][y][x]
+			}
+		}
+	}
+	return dInput
+}
+
 // Flatten Layer
-type FlattenLayer struct{}
+type FlattenLayer struct {
+	lastShape [3]int // channels, height, width
+}
+
+func (fl *FlattenLayer) Params() []*Param { return nil }
+
+// GobEncode and GobDecode satisfy gob.GobEncoder/GobDecoder with a no-op
+// payload. FlattenLayer carries no persistent state (lastShape is a
+// Forward-time cache, like every other layer's), and gob's default
+// reflection-based encoding errors on a struct with no exported fields at
+// all, which would otherwise break Sequential.Save whenever a FlattenLayer
+// is in the stack.
+func (fl *FlattenLayer) GobEncode() ([]byte, error) { return []byte{}, nil }
+func (fl *FlattenLayer) GobDecode([]byte) error     { return nil }
 
-func (fl *FlattenLayer) Forward(input [][][]float64) []float64 {
+func (fl *FlattenLayer) Forward(in any) any {
+	input := in.([][][]float64)
+	fl.lastShape = [3]int{len(input), len(input[0]), len(input[0][0])}
 	size := len(input) * len(input[0]) * len(input[0][0])
 	output := make([]float64, size)
 	i := 0
@@ -129,24 +318,105 @@ func (fl *FlattenLayer) Forward(input [][][]float64) []float64 {
 	return output
 }
 
+// Backward reshapes a flat gradient back into the [channels][h][w] tensor
+// shape seen by the preceding Forward call.
+func (fl *FlattenLayer) Backward(dOutArg any) any {
+	dOut := dOutArg.([]float64)
+	channels, h, w := fl.lastShape[0], fl.lastShape[1], fl.lastShape[2]
+	dInput := make([][][]float64, channels)
+	i := 0
+	for c := range dInput {
+		dInput[c] = make([][]float64, h)
+		for y := range dInput[c] {
+			dInput[c][y] = make([]float64, w)
+			for x := range dInput[c][y] {
+				dInput[c][y][x] = dOut[i]
+				i++
+			}
+		}
+	}
+	return dInput
+}
+
+// Activation selects the nonlinearity DenseLayer.Forward applies to its
+// output.
+type Activation int
+
+const (
+	// ReLU is the zero value so existing hidden-layer literals (which never
+	// set Activation) keep clamping at zero, as before.
+	ReLU Activation = iota
+	// Linear applies no nonlinearity, leaving raw logits untouched. Use this
+	// on a network's final DenseLayer: ReLU would clamp negative logits to
+	// zero and kill their gradient in Backward, even when the correct class
+	// is the one with a negative pre-activation.
+	Linear
+)
+
 // Dense (Fully Connected) Layer
 type DenseLayer struct {
-	Weights [][]float64
-	Biases  []float64
+	Weights    [][]float64
+	Biases     []float64
+	Activation Activation
+
+	weightGrads [][]float64
+	biasGrads   []float64
+
+	lastInput  []float64
+	lastOutput []float64
 }
 
-func (dl *DenseLayer) Forward(input []float64) []float64 {
+func (dl *DenseLayer) Params() []*Param {
+	var params []*Param
+	for i := range dl.Weights {
+		for j := range dl.Weights[i] {
+			params = append(params, &Param{&dl.Weights[i][j], &dl.weightGrads[i][j]})
+		}
+		params = append(params, &Param{&dl.Biases[i], &dl.biasGrads[i]})
+	}
+	return params
+}
+
+func (dl *DenseLayer) Forward(in any) any {
+	input := in.([]float64)
 	output := make([]float64, len(dl.Biases))
 	for i := range output {
 		output[i] = dl.Biases[i]
 		for j := range input {
 			output[i] += dl.Weights[i][j] * input[j]
 		}
-		output[i] = relu(output[i])
+		if dl.Activation == ReLU {
+			output[i] = relu(output[i])
+		}
 	}
+
+	dl.lastInput = input
+	dl.lastOutput = output
 	return output
 }
 
+// Backward accumulates weightGrads/biasGrads and returns the gradient
+// w.r.t. the input. Forward must be called first.
+func (dl *DenseLayer) Backward(dOutArg any) any {
+	dOut := dOutArg.([]float64)
+	dl.weightGrads = zerosLike2D(dl.Weights)
+	dl.biasGrads = make([]float64, len(dl.Biases))
+	dInput := make([]float64, len(dl.lastInput))
+
+	for i := range dOut {
+		if dl.Activation == ReLU && dl.lastOutput[i] <= 0 {
+			continue // ReLU gradient is zero past the activation
+		}
+		d := dOut[i]
+		dl.biasGrads[i] = d
+		for j := range dl.lastInput {
+			dl.weightGrads[i][j] = d * dl.lastInput[j]
+			dInput[j] += d * dl.Weights[i][j]
+		}
+	}
+	return dInput
+}
+
 // Utility functions
 func pad2D(input [][]float64, padding int) [][]float64 {
 	if padding == 0 {
@@ -164,6 +434,45 @@ func pad2D(input [][]float64, padding int) [][]float64 {
 	return padded
 }
 
+// unpad2D strips the border added by pad2D, returning the interior region.
+// It is the Backward-side counterpart used to route gradients back onto the
+// original (unpadded) input.
+func unpad2D(padded [][]float64, padding int) [][]float64 {
+	if padding == 0 {
+		return padded
+	}
+	h := len(padded) - 2*padding
+	w := len(padded[0]) - 2*padding
+	out := make([][]float64, h)
+	for i := range out {
+		out[i] = make([]float64, w)
+		copy(out[i], padded[i+padding][padding:padding+w])
+	}
+	return out
+}
+
+func zerosLike2D(m [][]float64) [][]float64 {
+	out := make([][]float64, len(m))
+	for i := range out {
+		out[i] = make([]float64, len(m[i]))
+	}
+	return out
+}
+
+func zerosLike4D(m [][][][]float64) [][][][]float64 {
+	out := make([][][][]float64, len(m))
+	for f := range out {
+		out[f] = make([][][]float64, len(m[f]))
+		for c := range out[f] {
+			out[f][c] = make([][]float64, len(m[f][c]))
+			for y := range out[f][c] {
+				out[f][c][y] = make([]float64, len(m[f][c][y]))
+			}
+		}
+	}
+	return out
+}
+
 func relu(x float64) float64 {
 	return math.Max(0, x)
 }
@@ -199,101 +508,67 @@ func randomArray(size int) []float64 {
 	return arr
 }
 
-func printDimensions(label string, x [][][]float64) {
-	fmt.Printf("%s: %d channels %dx%d\n", 
-		label, 
-		len(x), 
-		len(x[0]), 
-		len(x[0][0]))
-}
-
 func main() {
-	// Load and prepare image
-	file, err := os.Open("android_Ninja.png")
-	if err != nil {
-		panic(err)
-	}
-	defer file.Close()
-
-	img, _, err := image.Decode(file)
+	// Load and prepare image: imageprep.LoadTensor corrects EXIF orientation
+	// and resizes with a Lanczos filter instead of nearest-neighbor sampling.
+	mean, std := imageprep.NoNormalization(1)
+	input, err := imageprep.LoadTensor("android_Ninja.png", imageprep.Options{
+		Width:    28,
+		Height:   28,
+		Channels: 1,
+		Resample: imageprep.Lanczos,
+		Mean:     mean,
+		Std:      std,
+	})
 	if err != nil {
 		panic(err)
 	}
 
-	// Convert to 28x28 grayscale
-	resized := image.NewGray(image.Rect(0, 0, 28, 28))
-	bounds := img.Bounds()
-	for y := 0; y < 28; y++ {
-		for x := 0; x < 28; x++ {
-			// Simple resize by cropping/scaling
-			srcX := bounds.Min.X + x*bounds.Dx()/28
-			srcY := bounds.Min.Y + y*bounds.Dy()/28
-			resized.SetGray(x, y, color.GrayModel.Convert(img.At(srcX, srcY)).(color.Gray))
-		}
-	}
-
-	// Create input tensor
-	input := make([][][]float64, 1)
-	input[0] = make([][]float64, 28)
-	for y := 0; y < 28; y++ {
-		input[0][y] = make([]float64, 28)
-		for x := 0; x < 28; x++ {
-			input[0][y][x] = float64(resized.GrayAt(x, y).Y) / 255.0
-		}
-	}
-
-	// Initialize network
+	// Build the network. LRN sits between conv1 and pool1, as in AlexNet.
 	rand.Seed(time.Now().UnixNano())
 
-	conv1 := &ConvLayer{
+	net := NewSequential()
+	net.Add(&ConvLayer{
 		Filters: randomFilters(3, 1, 3, 3),
 		Biases:  randomArray(3),
 		Stride:  1,
 		Padding: 1,
-	}
-
-	pool1 := &MaxPoolLayer{PoolSize: 2, Stride: 2}
-
-	conv2 := &ConvLayer{
+	})
+	net.Add(NewLRNLayer())
+	net.Add(&MaxPoolLayer{PoolSize: 2, Stride: 2})
+	net.Add(&ConvLayer{
 		Filters: randomFilters(5, 3, 3, 3),
 		Biases:  randomArray(5),
 		Stride:  1,
 		Padding: 1,
-	}
-
-	pool2 := &MaxPoolLayer{PoolSize: 2, Stride: 2}
-
-	flat := &FlattenLayer{}
-
-	// Forward pass with dimension checks
-	x := conv1.Forward(input)
-	printDimensions("After conv1", x)
-
-	x = pool1.Forward(x)
-	printDimensions("After pool1", x)
-
-	x = conv2.Forward(x)
-	printDimensions("After conv2", x)
-
-	x = pool2.Forward(x)
-	printDimensions("After pool2", x)
-
-	flatX := flat.Forward(x)
+	})
+	net.Add(&MaxPoolLayer{PoolSize: 2, Stride: 2})
+	net.Add(&FlattenLayer{})
+
+	// One forward pass through the conv/pool/flatten stack to discover the
+	// flattened feature size, which depends on the input resolution and
+	// therefore can't be hard-coded.
+	flatX := net.Forward(input).([]float64)
 	fmt.Printf("Flattened size: %d\n", len(flatX))
 
-	// Dynamic dense layer initialization
-	dense1 := &DenseLayer{
+	net.Add(&DenseLayer{
 		Weights: randomMatrix(128, len(flatX)),
 		Biases:  randomArray(128),
+	})
+	net.Add(&DenseLayer{
+		Weights:    randomMatrix(10, 128),
+		Biases:     randomArray(10),
+		Activation: Linear,
+	})
+
+	// This repo ships a single labeled image rather than a real dataset, so
+	// the demo trains on one repeated sample just to exercise the loop.
+	batches := [][]Sample{
+		{{Image: input, Label: 0}},
 	}
 
-	denseOut := dense1.Forward(flatX)
-	
-	dense2 := &DenseLayer{
-		Weights: randomMatrix(10, 128),
-		Biases:  randomArray(10),
-	}
+	Train(net, 5, batches, CrossEntropyLoss{}, &Adam{LR: 0.001, Beta1: 0.9, Beta2: 0.999, Epsilon: 1e-8})
 
-	output := dense2.Forward(denseOut)
+	output := net.Forward(input).([]float64)
 	fmt.Println("Final output:", output)
 }
\ No newline at end of file
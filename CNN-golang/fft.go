@@ -0,0 +1,169 @@
+package main
+
+import "math"
+
+// ConvAlgorithm selects how ConvLayer.Forward computes its correlation.
+type ConvAlgorithm int
+
+const (
+	// Auto picks Direct or FFT based on kernel size; see ConvLayer.Forward.
+	Auto ConvAlgorithm = iota
+	Direct
+	FFT
+)
+
+// convolveFFT computes the same per-filter correlation as convolveDirect,
+// but per (filter, input-channel) pair via FFT instead of a direct sliding
+// window: zero-pad the padded input channel and the kernel to a common
+// M x N (both powers of two, M,N >= paddedH/W + kernelH/W - 1), multiply the
+// input's spectrum by the conjugate of the kernel's spectrum, inverse-FFT,
+// and read out the valid region at the configured stride. Channels are
+// summed before the bias and ReLU are applied.
+func (cl *ConvLayer) convolveFFT(padded [][][]float64, outH, outW int) [][][]float64 {
+	numFilters := len(cl.Filters)
+	inChannels := len(padded)
+	kernelH := len(cl.Filters[0][0])
+	kernelW := len(cl.Filters[0][0][0])
+	paddedH := len(padded[0])
+	paddedW := len(padded[0][0])
+
+	validH := paddedH - kernelH + 1
+	validW := paddedW - kernelW + 1
+	m := nextPow2(paddedH + kernelH - 1)
+	n := nextPow2(paddedW + kernelW - 1)
+
+	// Input spectra don't depend on the filter, so compute them once per
+	// channel and reuse across filters.
+	inputSpecs := make([][][]complex128, inChannels)
+	for c := range inputSpecs {
+		grid := zeroPadComplex(padded[c], m, n)
+		fft2D(grid, false)
+		inputSpecs[c] = grid
+	}
+
+	output := make([][][]float64, numFilters)
+	for f := 0; f < numFilters; f++ {
+		output[f] = make([][]float64, outH)
+		for i := range output[f] {
+			output[f][i] = make([]float64, outW)
+		}
+
+		fullCorr := make([][]float64, validH)
+		for y := range fullCorr {
+			fullCorr[y] = make([]float64, validW)
+		}
+
+		for c := 0; c < inChannels; c++ {
+			kernelGrid := zeroPadComplex(cl.Filters[f][c], m, n)
+			fft2D(kernelGrid, false)
+
+			prod := make([][]complex128, m)
+			for y := range prod {
+				prod[y] = make([]complex128, n)
+				for x := range prod[y] {
+					prod[y][x] = inputSpecs[c][y][x] * complex(real(kernelGrid[y][x]), -imag(kernelGrid[y][x]))
+				}
+			}
+			fft2D(prod, true)
+
+			for y := 0; y < validH; y++ {
+				for x := 0; x < validW; x++ {
+					fullCorr[y][x] += real(prod[y][x])
+				}
+			}
+		}
+
+		for y := 0; y < outH; y++ {
+			for x := 0; x < outW; x++ {
+				output[f][y][x] = relu(fullCorr[y*cl.Stride][x*cl.Stride] + cl.Biases[f])
+			}
+		}
+	}
+	return output
+}
+
+func nextPow2(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+func zeroPadComplex(src [][]float64, m, n int) [][]complex128 {
+	grid := make([][]complex128, m)
+	for y := range grid {
+		grid[y] = make([]complex128, n)
+	}
+	for y := range src {
+		for x := range src[y] {
+			grid[y][x] = complex(src[y][x], 0)
+		}
+	}
+	return grid
+}
+
+// fft2D runs a 2D FFT (or its inverse, when inverse is true) on grid in
+// place by transforming every row then every column. len(grid) and
+// len(grid[0]) must both be powers of two.
+func fft2D(grid [][]complex128, inverse bool) {
+	for _, row := range grid {
+		fft1D(row, inverse)
+	}
+	col := make([]complex128, len(grid))
+	for x := range grid[0] {
+		for y := range grid {
+			col[y] = grid[y][x]
+		}
+		fft1D(col, inverse)
+		for y := range grid {
+			grid[y][x] = col[y]
+		}
+	}
+}
+
+// fft1D is an in-place radix-2 Cooley-Tukey FFT (or inverse DFT when
+// inverse is true). len(a) must be a power of two.
+func fft1D(a []complex128, inverse bool) {
+	n := len(a)
+	if n <= 1 {
+		return
+	}
+
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+
+	for length := 2; length <= n; length <<= 1 {
+		angle := 2 * math.Pi / float64(length)
+		if !inverse {
+			angle = -angle
+		}
+		wLen := complex(math.Cos(angle), math.Sin(angle))
+		for i := 0; i < n; i += length {
+			w := complex(1.0, 0.0)
+			half := length / 2
+			for j := 0; j < half; j++ {
+				u := a[i+j]
+				v := a[i+j+half] * w
+				a[i+j] = u + v
+				a[i+j+half] = u - v
+				w *= wLen
+			}
+		}
+	}
+
+	if inverse {
+		scale := complex(float64(n), 0)
+		for i := range a {
+			a[i] /= scale
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// TestConvolveFFTMatchesDirect checks that convolveFFT and convolveDirect
+// agree to within numerical noise across a range of kernel sizes, channel
+// counts, strides, and padding, since convolveFFT is only exercised by
+// Forward when Algorithm is FFT or the kernel is large enough for Auto to
+// pick it.
+func TestConvolveFFTMatchesDirect(t *testing.T) {
+	cases := []struct {
+		name              string
+		filters, channels int
+		kernelH, kernelW  int
+		inputH, inputW    int
+		stride, padding   int
+	}{
+		{"3x3 stride1 pad1", 2, 2, 3, 3, 8, 8, 1, 1},
+		{"5x5 stride1 pad2", 2, 3, 5, 5, 10, 10, 1, 2},
+		{"5x5 stride2 pad2", 2, 3, 5, 5, 11, 13, 2, 2},
+		{"7x7 stride1 pad0", 1, 1, 7, 7, 9, 9, 1, 0},
+		{"6x4 stride3 pad1", 2, 2, 6, 4, 14, 10, 3, 1},
+	}
+
+	rng := rand.New(rand.NewSource(42))
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cl := &ConvLayer{
+				Filters: randomFiltersFrom(rng, tc.filters, tc.channels, tc.kernelH, tc.kernelW),
+				Biases:  randomArrayFrom(rng, tc.filters),
+				Stride:  tc.stride,
+				Padding: tc.padding,
+			}
+			input := randomTensor(tc.channels, tc.inputH, tc.inputW, rng)
+
+			padded := make([][][]float64, tc.channels)
+			for c := range padded {
+				padded[c] = pad2D(input[c], cl.Padding)
+			}
+			outH := (tc.inputH+2*tc.padding-tc.kernelH)/tc.stride + 1
+			outW := (tc.inputW+2*tc.padding-tc.kernelW)/tc.stride + 1
+
+			direct := cl.convolveDirect(padded, outH, outW)
+			fft := cl.convolveFFT(padded, outH, outW)
+
+			for f := 0; f < tc.filters; f++ {
+				for y := 0; y < outH; y++ {
+					for x := 0; x < outW; x++ {
+						diff := math.Abs(direct[f][y][x] - fft[f][y][x])
+						if diff > 1e-9 {
+							t.Fatalf("filter %d (%d,%d): direct %v, fft %v, diff %v", f, y, x, direct[f][y][x], fft[f][y][x], diff)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func randomFiltersFrom(rng *rand.Rand, num, channels, height, width int) [][][][]float64 {
+	filters := make([][][][]float64, num)
+	for f := range filters {
+		filters[f] = make([][][]float64, channels)
+		for c := range filters[f] {
+			filters[f][c] = make([][]float64, height)
+			for h := range filters[f][c] {
+				row := make([]float64, width)
+				for w := range row {
+					row[w] = rng.NormFloat64() * 0.1
+				}
+				filters[f][c][h] = row
+			}
+		}
+	}
+	return filters
+}
+
+func randomArrayFrom(rng *rand.Rand, size int) []float64 {
+	arr := make([]float64, size)
+	for i := range arr {
+		arr[i] = rng.NormFloat64() * 0.1
+	}
+	return arr
+}
@@ -0,0 +1,141 @@
+package main
+
+import "math"
+
+// LRNLayer is AlexNet-style Local Response Normalization across a window of
+// neighboring channels at each spatial position:
+//
+//	output[c][y][x] = input[c][y][x] / (K + Alpha*sum)^Beta
+//
+// where sum ranges over input[p][y][x]^2 for p in [c-N/2, c+N/2].
+type LRNLayer struct {
+	N     int // channel window size, odd
+	K     float64
+	Alpha float64
+	Beta  float64
+
+	lastInput [][][]float64 // cached for Backward
+}
+
+// NewLRNLayer returns an LRNLayer with the hyperparameters used by AlexNet
+// (N=5, K=2, Alpha=1e-4, Beta=0.75).
+func NewLRNLayer() *LRNLayer {
+	return &LRNLayer{N: 5, K: 2, Alpha: 1e-4, Beta: 0.75}
+}
+
+func (l *LRNLayer) Params() []*Param { return nil }
+
+func (l *LRNLayer) Forward(in any) any {
+	input := in.([][][]float64)
+	channels := len(input)
+	h := len(input[0])
+	w := len(input[0][0])
+	half := l.N / 2
+
+	output := make([][][]float64, channels)
+	for c := range output {
+		output[c] = make([][]float64, h)
+		for y := range output[c] {
+			output[c][y] = make([]float64, w)
+		}
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			// Slide the window down the channel axis, keeping a running
+			// sum of squares so each position costs O(channels) rather
+			// than O(channels * N).
+			var sum float64
+			for p := 0; p <= half && p < channels; p++ {
+				sum += input[p][y][x] * input[p][y][x]
+			}
+			for c := 0; c < channels; c++ {
+				output[c][y][x] = input[c][y][x] / math.Pow(l.K+l.Alpha*sum, l.Beta)
+
+				trail := c - half
+				if trail >= 0 {
+					sum -= input[trail][y][x] * input[trail][y][x]
+				}
+				lead := c + half + 1
+				if lead < channels {
+					sum += input[lead][y][x] * input[lead][y][x]
+				}
+			}
+		}
+	}
+
+	l.lastInput = input
+	return output
+}
+
+// Backward takes the gradient of the loss w.r.t. the layer's output and
+// returns the gradient w.r.t. the input. Forward must be called first.
+//
+// Differentiating output[c] = input[c] * denom[c]^-Beta, where
+// denom[c] = K + Alpha*sum_{p in window(c)} input[p]^2, gives
+//
+//	dInput[j] = dOut[j]*denom[j]^-Beta
+//	            - 2*Alpha*Beta*input[j] * sum_{c in window(j)} dOut[c]*input[c]*denom[c]^(-Beta-1)
+//
+// using the fact that the window relation is symmetric (c in window(j) iff
+// j in window(c)). The inner sum is accumulated with the same sliding-window
+// trick as Forward, so this is also O(channels) per spatial position.
+func (l *LRNLayer) Backward(dOutArg any) any {
+	dOut := dOutArg.([][][]float64)
+	input := l.lastInput
+	channels := len(input)
+	h := len(input[0])
+	w := len(input[0][0])
+	half := l.N / 2
+
+	dInput := make([][][]float64, channels)
+	for c := range dInput {
+		dInput[c] = make([][]float64, h)
+		for y := range dInput[c] {
+			dInput[c][y] = make([]float64, w)
+		}
+	}
+
+	denom := make([]float64, channels)
+	term := make([]float64, channels)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var sum float64
+			for p := 0; p <= half && p < channels; p++ {
+				sum += input[p][y][x] * input[p][y][x]
+			}
+			for c := 0; c < channels; c++ {
+				denom[c] = l.K + l.Alpha*sum
+				term[c] = dOut[c][y][x] * input[c][y][x] * math.Pow(denom[c], -l.Beta-1)
+
+				trail := c - half
+				if trail >= 0 {
+					sum -= input[trail][y][x] * input[trail][y][x]
+				}
+				lead := c + half + 1
+				if lead < channels {
+					sum += input[lead][y][x] * input[lead][y][x]
+				}
+			}
+
+			var windowSum float64
+			for p := 0; p <= half && p < channels; p++ {
+				windowSum += term[p]
+			}
+			for j := 0; j < channels; j++ {
+				dInput[j][y][x] = dOut[j][y][x]*math.Pow(denom[j], -l.Beta) - 2*l.Alpha*l.Beta*input[j][y][x]*windowSum
+
+				trail := j - half
+				if trail >= 0 {
+					windowSum -= term[trail]
+				}
+				lead := j + half + 1
+				if lead < channels {
+					windowSum += term[lead]
+				}
+			}
+		}
+	}
+	return dInput
+}
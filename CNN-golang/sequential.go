@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+)
+
+// sequentialFormatVersion is bumped whenever the on-disk layout of
+// sequentialFile changes, so LoadSequential can reject files it can no
+// longer interpret instead of silently decoding garbage.
+const sequentialFormatVersion = 1
+
+func init() {
+	gob.Register(&ConvLayer{})
+	gob.Register(&MaxPoolLayer{})
+	gob.Register(&FlattenLayer{})
+	gob.Register(&DenseLayer{})
+	gob.Register(&LRNLayer{})
+}
+
+// Sequential is an ordered stack of Layers, each feeding its output straight
+// into the next layer's Forward. Layers disagree on tensor shape (a
+// FlattenLayer turns a [][][]float64 into a []float64, say), so Sequential
+// threads values through as `any` and leaves the type assertions to each
+// Layer's own Forward/Backward.
+type Sequential struct {
+	Layers []Layer
+}
+
+// NewSequential returns an empty Sequential ready for Add calls.
+func NewSequential() *Sequential {
+	return &Sequential{}
+}
+
+// Add appends layer to the end of the stack.
+func (s *Sequential) Add(layer Layer) {
+	s.Layers = append(s.Layers, layer)
+}
+
+// Forward runs input through every layer in order and returns the final
+// layer's output.
+func (s *Sequential) Forward(input any) any {
+	x := input
+	for _, l := range s.Layers {
+		x = l.Forward(x)
+	}
+	return x
+}
+
+// Backward runs dOut through every layer in reverse order and returns the
+// gradient w.r.t. the Sequential's original input. Forward must be called
+// first.
+func (s *Sequential) Backward(dOut any) any {
+	d := dOut
+	for i := len(s.Layers) - 1; i >= 0; i-- {
+		d = s.Layers[i].Backward(d)
+	}
+	return d
+}
+
+// Params collects the trainable parameters of every layer in the stack, in
+// layer order, for an Optimizer to update.
+func (s *Sequential) Params() []*Param {
+	var params []*Param
+	for _, l := range s.Layers {
+		params = append(params, l.Params()...)
+	}
+	return params
+}
+
+// sequentialFile is the gob-encoded payload written by Save and read back by
+// LoadSequential. Layer caches (lastInput and friends) are unexported and so
+// never reach disk; they're repopulated by the next Forward call.
+type sequentialFile struct {
+	Version int
+	Layers  []Layer
+}
+
+// Save writes the network's layers, including trainable weights, to path.
+func (s *Sequential) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return gob.NewEncoder(f).Encode(sequentialFile{
+		Version: sequentialFormatVersion,
+		Layers:  s.Layers,
+	})
+}
+
+// LoadSequential reads a network previously written by Sequential.Save.
+func LoadSequential(path string) (*Sequential, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var sf sequentialFile
+	if err := gob.NewDecoder(f).Decode(&sf); err != nil {
+		return nil, err
+	}
+	if sf.Version != sequentialFormatVersion {
+		return nil, fmt.Errorf("sequential: unsupported format version %d", sf.Version)
+	}
+
+	return &Sequential{Layers: sf.Layers}, nil
+}
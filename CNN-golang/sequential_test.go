@@ -0,0 +1,56 @@
+package main
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+func TestSequentialSaveLoadRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	net := NewSequential()
+	net.Add(&ConvLayer{
+		Filters: randomFilters(2, 1, 3, 3),
+		Biases:  randomArray(2),
+		Stride:  1,
+		Padding: 1,
+	})
+	net.Add(&MaxPoolLayer{PoolSize: 2, Stride: 2})
+	net.Add(&FlattenLayer{})
+	net.Add(&DenseLayer{
+		Weights: randomMatrix(3, 2*2*2),
+		Biases:  randomArray(3),
+	})
+
+	input := randomTensor(1, 4, 4, rng)
+	wantOutput := net.Forward(input).([]float64)
+	wantWeight := net.Layers[0].(*ConvLayer).Filters[0][0][0][0]
+
+	path := filepath.Join(t.TempDir(), "net.gob")
+	if err := net.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadSequential(path)
+	if err != nil {
+		t.Fatalf("LoadSequential: %v", err)
+	}
+	if len(loaded.Layers) != len(net.Layers) {
+		t.Fatalf("loaded %d layers, want %d", len(loaded.Layers), len(net.Layers))
+	}
+
+	gotWeight := loaded.Layers[0].(*ConvLayer).Filters[0][0][0][0]
+	if gotWeight != wantWeight {
+		t.Errorf("loaded weight = %v, want %v", gotWeight, wantWeight)
+	}
+
+	gotOutput := loaded.Forward(input).([]float64)
+	if len(gotOutput) != len(wantOutput) {
+		t.Fatalf("loaded output has %d entries, want %d", len(gotOutput), len(wantOutput))
+	}
+	for i := range wantOutput {
+		if gotOutput[i] != wantOutput[i] {
+			t.Errorf("output[%d] = %v, want %v", i, gotOutput[i], wantOutput[i])
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Sample is one labeled training example: a CHW image tensor and the index
+// of its correct class.
+type Sample struct {
+	Image [][][]float64
+	Label int
+}
+
+// Loss computes a scalar loss and its gradient w.r.t. the network's final
+// output (the last layer's post-activation values, raw logits when that
+// layer is Linear).
+type Loss interface {
+	Forward(pred []float64, label int) float64
+	Backward(pred []float64, label int) []float64
+}
+
+// CrossEntropyLoss applies softmax to pred internally and evaluates the
+// negative log-likelihood of the correct class.
+type CrossEntropyLoss struct{}
+
+func (CrossEntropyLoss) Forward(pred []float64, label int) float64 {
+	probs := softmax(pred)
+	return -math.Log(probs[label] + 1e-12)
+}
+
+func (CrossEntropyLoss) Backward(pred []float64, label int) []float64 {
+	probs := softmax(pred)
+	grad := make([]float64, len(pred))
+	copy(grad, probs)
+	grad[label] -= 1
+	return grad
+}
+
+// MSELoss is the mean squared error against a one-hot target.
+type MSELoss struct{}
+
+func (MSELoss) Forward(pred []float64, label int) float64 {
+	var sum float64
+	for i, p := range pred {
+		target := 0.0
+		if i == label {
+			target = 1.0
+		}
+		d := p - target
+		sum += d * d
+	}
+	return sum / float64(len(pred))
+}
+
+func (MSELoss) Backward(pred []float64, label int) []float64 {
+	grad := make([]float64, len(pred))
+	for i, p := range pred {
+		target := 0.0
+		if i == label {
+			target = 1.0
+		}
+		grad[i] = 2 * (p - target) / float64(len(pred))
+	}
+	return grad
+}
+
+func softmax(x []float64) []float64 {
+	max := x[0]
+	for _, v := range x {
+		if v > max {
+			max = v
+		}
+	}
+	out := make([]float64, len(x))
+	var sum float64
+	for i, v := range x {
+		out[i] = math.Exp(v - max)
+		sum += out[i]
+	}
+	for i := range out {
+		out[i] /= sum
+	}
+	return out
+}
+
+// Optimizer updates a layer's parameters in place from their cached
+// gradients, computed by a prior call to the layer's Backward method.
+type Optimizer interface {
+	Step(params []*Param)
+}
+
+// SGD is plain stochastic gradient descent: value -= LR * grad.
+type SGD struct {
+	LR float64
+}
+
+func (o *SGD) Step(params []*Param) {
+	for _, p := range params {
+		*p.Value -= o.LR * *p.Grad
+	}
+}
+
+// Momentum is SGD with a velocity term accumulated per parameter.
+type Momentum struct {
+	LR       float64
+	Beta     float64
+	velocity map[*float64]float64
+}
+
+func (o *Momentum) Step(params []*Param) {
+	if o.velocity == nil {
+		o.velocity = make(map[*float64]float64)
+	}
+	for _, p := range params {
+		v := o.Beta*o.velocity[p.Value] + (1-o.Beta)**p.Grad
+		o.velocity[p.Value] = v
+		*p.Value -= o.LR * v
+	}
+}
+
+// Adam is the Adam optimizer (Kingma & Ba, 2014) with bias-corrected first
+// and second moment estimates.
+type Adam struct {
+	LR      float64
+	Beta1   float64
+	Beta2   float64
+	Epsilon float64
+
+	t int
+	m map[*float64]float64
+	v map[*float64]float64
+}
+
+func (o *Adam) Step(params []*Param) {
+	if o.m == nil {
+		o.m = make(map[*float64]float64)
+		o.v = make(map[*float64]float64)
+	}
+	o.t++
+	biasCorr1 := 1 - math.Pow(o.Beta1, float64(o.t))
+	biasCorr2 := 1 - math.Pow(o.Beta2, float64(o.t))
+	for _, p := range params {
+		g := *p.Grad
+		m := o.Beta1*o.m[p.Value] + (1-o.Beta1)*g
+		v := o.Beta2*o.v[p.Value] + (1-o.Beta2)*g*g
+		o.m[p.Value] = m
+		o.v[p.Value] = v
+		mHat := m / biasCorr1
+		vHat := v / biasCorr2
+		*p.Value -= o.LR * mHat / (math.Sqrt(vHat) + o.Epsilon)
+	}
+}
+
+// Train runs stochastic gradient descent over batches of labeled samples for
+// the given number of epochs, printing the mean loss after each epoch.
+func Train(net *Sequential, epochs int, batches [][]Sample, loss Loss, opt Optimizer) {
+	for epoch := 0; epoch < epochs; epoch++ {
+		var epochLoss float64
+		var count int
+		for _, batch := range batches {
+			for _, sample := range batch {
+				pred := net.Forward(sample.Image).([]float64)
+				epochLoss += loss.Forward(pred, sample.Label)
+				count++
+
+				dOut := loss.Backward(pred, sample.Label)
+				net.Backward(dOut)
+				opt.Step(net.Params())
+			}
+		}
+		fmt.Printf("epoch %d: mean loss %.6f\n", epoch+1, epochLoss/float64(count))
+	}
+}
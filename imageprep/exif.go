@@ -0,0 +1,162 @@
+package imageprep
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"image"
+	"io"
+)
+
+// readJPEGOrientation scans a JPEG's segments for an Exif APP1 block and
+// returns its Orientation tag (1-8). It returns 1 (already upright) if the
+// stream has no Exif metadata or no Orientation tag. r must be positioned
+// at the start of the JPEG stream.
+func readJPEGOrientation(r io.Reader) (int, error) {
+	br := bufio.NewReader(r)
+
+	var soi [2]byte
+	if _, err := io.ReadFull(br, soi[:]); err != nil {
+		return 1, err
+	}
+	if soi[0] != 0xFF || soi[1] != 0xD8 {
+		return 1, errors.New("imageprep: not a JPEG stream")
+	}
+
+	for {
+		marker, err := nextMarker(br)
+		if err != nil {
+			return 1, nil // no Exif segment found; treat as already upright
+		}
+		if marker == 0xD9 || marker == 0xDA || (marker >= 0xD0 && marker <= 0xD7) {
+			return 1, nil // end of image, start of scan, or a standalone RST marker
+		}
+
+		var lenBuf [2]byte
+		if _, err := io.ReadFull(br, lenBuf[:]); err != nil {
+			return 1, nil
+		}
+		segLen := int(binary.BigEndian.Uint16(lenBuf[:])) - 2
+		if segLen < 0 {
+			return 1, nil
+		}
+		data := make([]byte, segLen)
+		if _, err := io.ReadFull(br, data); err != nil {
+			return 1, nil
+		}
+
+		if marker == 0xE1 && len(data) > 6 && string(data[:6]) == "Exif\x00\x00" {
+			return parseExifOrientation(data[6:])
+		}
+	}
+}
+
+// nextMarker advances br past any fill bytes and returns the next marker
+// byte following an 0xFF.
+func nextMarker(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != 0xFF {
+			continue
+		}
+		marker, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if marker == 0xFF || marker == 0x00 {
+			continue // fill byte
+		}
+		return marker, nil
+	}
+}
+
+// parseExifOrientation reads the Orientation tag (0x0112) out of a TIFF
+// header, the format Exif metadata is embedded in.
+func parseExifOrientation(tiff []byte) (int, error) {
+	if len(tiff) < 8 {
+		return 1, errors.New("imageprep: truncated TIFF header")
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1, errors.New("imageprep: bad TIFF byte order marker")
+	}
+
+	ifdOffset := order.Uint32(tiff[4:8])
+	if int(ifdOffset)+2 > len(tiff) {
+		return 1, errors.New("imageprep: IFD offset out of range")
+	}
+
+	numEntries := int(order.Uint16(tiff[ifdOffset : ifdOffset+2]))
+	entries := tiff[ifdOffset+2:]
+	const entrySize = 12
+	for i := 0; i < numEntries; i++ {
+		start := i * entrySize
+		if start+entrySize > len(entries) {
+			break
+		}
+		entry := entries[start : start+entrySize]
+		const orientationTag = 0x0112
+		if order.Uint16(entry[0:2]) == orientationTag {
+			return int(order.Uint16(entry[8:10])), nil
+		}
+	}
+	return 1, nil // no Orientation tag present
+}
+
+// applyOrientation returns img rotated/flipped according to the EXIF
+// Orientation tag (1-8) so a photo taken sideways or upside down by a
+// camera or phone displays upright.
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	outW, outH := w, h
+	if orientation >= 5 {
+		outW, outH = h, w
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, outW, outH))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dx, dy := orientedCoords(orientation, x, y, w, h)
+			out.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return out
+}
+
+// orientedCoords maps a source pixel at (x, y) in a w x h image to its
+// destination coordinates under the given EXIF orientation.
+func orientedCoords(orientation, x, y, w, h int) (int, int) {
+	switch orientation {
+	case 2: // mirror horizontal
+		return w - 1 - x, y
+	case 3: // rotate 180
+		return w - 1 - x, h - 1 - y
+	case 4: // mirror vertical
+		return x, h - 1 - y
+	case 5: // transpose (mirror horizontal + rotate 270 CW)
+		return y, x
+	case 6: // rotate 90 CW
+		return h - 1 - y, x
+	case 7: // transverse (mirror horizontal + rotate 90 CW)
+		return h - 1 - y, w - 1 - x
+	case 8: // rotate 270 CW
+		return y, w - 1 - x
+	default:
+		return x, y
+	}
+}
@@ -0,0 +1,127 @@
+package imageprep
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// cornerImage builds a w x h RGBA image with four distinguishable solid
+// quadrants, so an orientation transform's effect on each corner can be
+// checked directly.
+func cornerImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	colors := [4]color.RGBA{
+		{255, 0, 0, 255},   // top-left
+		{0, 255, 0, 255},   // top-right
+		{0, 0, 255, 255},   // bottom-left
+		{255, 255, 0, 255}, // bottom-right
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			idx := 0
+			if x >= w/2 {
+				idx |= 1
+			}
+			if y >= h/2 {
+				idx |= 2
+			}
+			img.Set(x, y, colors[idx])
+		}
+	}
+	return img
+}
+
+func TestApplyOrientationRotate90CW(t *testing.T) {
+	// orientation 6 rotates 90 CW, so the original top-left corner (red)
+	// ends up in the top-right of the (now-transposed) output.
+	src := cornerImage(4, 2)
+	out := applyOrientation(src, 6)
+
+	bounds := out.Bounds()
+	if bounds.Dx() != 2 || bounds.Dy() != 4 {
+		t.Fatalf("rotated bounds = %v, want 2x4", bounds)
+	}
+	topLeft := src.At(0, 0)
+	gotTopRight := out.At(1, 0)
+	if topLeft != gotTopRight {
+		t.Errorf("top-left source pixel %v did not land at output top-right, got %v", topLeft, gotTopRight)
+	}
+}
+
+func TestApplyOrientationIdentityAndNoop(t *testing.T) {
+	src := cornerImage(4, 4)
+	for _, orientation := range []int{0, 1, 9, -1} {
+		out := applyOrientation(src, orientation)
+		if out != image.Image(src) {
+			t.Errorf("orientation %d should be a no-op, got a different image", orientation)
+		}
+	}
+}
+
+func TestOrientedCoordsRoundTrips(t *testing.T) {
+	// Applying an orientation's inverse should return the original pixel.
+	// Orientations 2, 3, 4 are involutions that keep the image's w x h, so
+	// applying the same orientation twice with the same w, h round-trips.
+	// 5 and 7 are also involutions but swap w and h, and 6 and 8 are each
+	// other's inverse, so this test covers only 2, 3, 4.
+	w, h := 5, 3
+	for _, orientation := range []int{2, 3, 4} {
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				dx, dy := orientedCoords(orientation, x, y, w, h)
+				bx, by := orientedCoords(orientation, dx, dy, w, h)
+				if bx != x || by != y {
+					t.Fatalf("orientation %d: (%d,%d) -> (%d,%d) -> (%d,%d), want round-trip", orientation, x, y, dx, dy, bx, by)
+				}
+			}
+		}
+	}
+}
+
+// syntheticTIFF builds a minimal little-endian TIFF header with a single
+// IFD entry for the given tag/value, mirroring the layout
+// parseExifOrientation expects to find embedded in a JPEG's Exif segment.
+func syntheticTIFF(tag uint16, value uint16) []byte {
+	buf := make([]byte, 8+2+12+4)
+	copy(buf[0:2], "II")
+	binary.LittleEndian.PutUint16(buf[2:4], 42)
+	binary.LittleEndian.PutUint32(buf[4:8], 8)
+	binary.LittleEndian.PutUint16(buf[8:10], 1) // one IFD entry
+
+	entry := buf[10:22]
+	binary.LittleEndian.PutUint16(entry[0:2], tag)
+	binary.LittleEndian.PutUint16(entry[2:4], 3) // type SHORT
+	binary.LittleEndian.PutUint32(entry[4:8], 1) // count
+	binary.LittleEndian.PutUint16(entry[8:10], value)
+	return buf
+}
+
+func TestParseExifOrientationFound(t *testing.T) {
+	tiff := syntheticTIFF(0x0112, 6)
+	orientation, err := parseExifOrientation(tiff)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if orientation != 6 {
+		t.Errorf("orientation = %d, want 6", orientation)
+	}
+}
+
+func TestParseExifOrientationMissingTagDefaultsUpright(t *testing.T) {
+	tiff := syntheticTIFF(0x0110, 6) // unrelated tag (Model), not Orientation
+	orientation, err := parseExifOrientation(tiff)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if orientation != 1 {
+		t.Errorf("orientation = %d, want 1 (default upright)", orientation)
+	}
+}
+
+func TestParseExifOrientationTruncated(t *testing.T) {
+	if _, err := parseExifOrientation([]byte{0x49, 0x49}); err == nil {
+		t.Error("expected an error for a truncated TIFF header, got nil")
+	}
+}
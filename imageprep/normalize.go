@@ -0,0 +1,25 @@
+package imageprep
+
+// ImageNetMeanRGB and ImageNetStdRGB are the per-channel (R, G, B)
+// normalization statistics used by models pretrained on ImageNet.
+var (
+	ImageNetMeanRGB = []float64{0.485, 0.456, 0.406}
+	ImageNetStdRGB  = []float64{0.229, 0.224, 0.225}
+)
+
+// GrayscaleMean and GrayscaleStd center a single grayscale channel to
+// roughly [-1, 1], a common default absent a task-specific statistic.
+var (
+	GrayscaleMean = []float64{0.5}
+	GrayscaleStd  = []float64{0.5}
+)
+
+// NoNormalization returns Mean/Std values that leave pixels in [0, 1].
+func NoNormalization(channels int) (mean, std []float64) {
+	mean = make([]float64, channels)
+	std = make([]float64, channels)
+	for i := range std {
+		std[i] = 1
+	}
+	return mean, std
+}
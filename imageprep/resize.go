@@ -0,0 +1,174 @@
+package imageprep
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// resize scales img to width x height by convolving each color plane with a
+// separable resampling filter, first along X then along Y.
+func resize(img image.Image, width, height int, filter ResampleFilter) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	var planes [3][][]float64
+	for c := range planes {
+		planes[c] = make([][]float64, srcH)
+		for y := range planes[c] {
+			planes[c][y] = make([]float64, srcW)
+		}
+	}
+	for y := 0; y < srcH; y++ {
+		for x := 0; x < srcW; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			planes[0][y][x] = float64(r) / 65535.0
+			planes[1][y][x] = float64(g) / 65535.0
+			planes[2][y][x] = float64(b) / 65535.0
+		}
+	}
+
+	kernel, support := filterKernel(filter)
+
+	var resized [3][][]float64
+	for c := range planes {
+		horiz := resizeRows(planes[c], width, kernel, support)
+		resized[c] = resizeCols(horiz, height, kernel, support)
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			out.SetRGBA(x, y, color.RGBA{
+				R: toByte(resized[0][y][x]),
+				G: toByte(resized[1][y][x]),
+				B: toByte(resized[2][y][x]),
+				A: 255,
+			})
+		}
+	}
+	return out
+}
+
+// resizeRows resamples each row of data, whose width is len(data[0]), to
+// newWidth.
+func resizeRows(data [][]float64, newWidth int, kernel func(float64) float64, support float64) [][]float64 {
+	h := len(data)
+	srcW := len(data[0])
+	out := make([][]float64, h)
+	for y := range out {
+		out[y] = make([]float64, newWidth)
+	}
+
+	scale, filterScale, radius := samplingParams(srcW, newWidth, support)
+	for x := 0; x < newWidth; x++ {
+		srcX := (float64(x)+0.5)*scale - 0.5
+		lo, hi := int(math.Floor(srcX-radius)), int(math.Ceil(srcX+radius))
+		for y := 0; y < h; y++ {
+			var sum, weightSum float64
+			for sx := lo; sx <= hi; sx++ {
+				w := kernel((srcX - float64(sx)) / filterScale)
+				sum += data[y][clampInt(sx, 0, srcW-1)] * w
+				weightSum += w
+			}
+			if weightSum != 0 {
+				out[y][x] = sum / weightSum
+			}
+		}
+	}
+	return out
+}
+
+// resizeCols resamples each column of data, whose height is len(data), to
+// newHeight.
+func resizeCols(data [][]float64, newHeight int, kernel func(float64) float64, support float64) [][]float64 {
+	srcH := len(data)
+	w := len(data[0])
+	out := make([][]float64, newHeight)
+	for y := range out {
+		out[y] = make([]float64, w)
+	}
+
+	scale, filterScale, radius := samplingParams(srcH, newHeight, support)
+	for y := 0; y < newHeight; y++ {
+		srcY := (float64(y)+0.5)*scale - 0.5
+		lo, hi := int(math.Floor(srcY-radius)), int(math.Ceil(srcY+radius))
+		for x := 0; x < w; x++ {
+			var sum, weightSum float64
+			for sy := lo; sy <= hi; sy++ {
+				wgt := kernel((srcY - float64(sy)) / filterScale)
+				sum += data[clampInt(sy, 0, srcH-1)][x] * wgt
+				weightSum += wgt
+			}
+			if weightSum != 0 {
+				out[y][x] = sum / weightSum
+			}
+		}
+	}
+	return out
+}
+
+// samplingParams returns the source-to-destination scale factor and the
+// kernel scale/radius to sample with. When downsampling (srcLen > dstLen),
+// the kernel is widened by the scale factor so it still averages over every
+// source sample that maps to one output sample, avoiding aliasing.
+func samplingParams(srcLen, dstLen int, support float64) (scale, filterScale, radius float64) {
+	scale = float64(srcLen) / float64(dstLen)
+	filterScale = math.Max(scale, 1)
+	radius = support * filterScale
+	return scale, filterScale, radius
+}
+
+func filterKernel(filter ResampleFilter) (kernel func(float64) float64, support float64) {
+	switch filter {
+	case Lanczos:
+		return lanczosKernel, 3
+	default:
+		return bilinearKernel, 1
+	}
+}
+
+func bilinearKernel(x float64) float64 {
+	x = math.Abs(x)
+	if x < 1 {
+		return 1 - x
+	}
+	return 0
+}
+
+// lanczosKernel is a windowed sinc with a 3-lobe support, the standard
+// Lanczos-3 resampling kernel.
+func lanczosKernel(x float64) float64 {
+	const a = 3
+	x = math.Abs(x)
+	if x >= a {
+		return 0
+	}
+	if x < 1e-8 {
+		return 1
+	}
+	piX := math.Pi * x
+	return a * math.Sin(piX) * math.Sin(piX/a) / (piX * piX)
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func toByte(v float64) uint8 {
+	v *= 255
+	switch {
+	case v < 0:
+		return 0
+	case v > 255:
+		return 255
+	default:
+		return uint8(v + 0.5)
+	}
+}
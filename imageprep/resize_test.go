@@ -0,0 +1,96 @@
+package imageprep
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// gradientImage builds a w x h grayscale image whose value increases
+// linearly from 0 to 255 along X, independent of Y — a synthetic test
+// pattern whose resampled response is easy to reason about.
+func gradientImage(w, h int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(float64(x) / float64(w-1) * 255)
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return img
+}
+
+func grayAt(img image.Image, x, y int) float64 {
+	r, _, _, _ := img.At(x, y).RGBA()
+	return float64(r) / 65535.0
+}
+
+func TestResizePreservesMonotoneGradient(t *testing.T) {
+	src := gradientImage(64, 64)
+	for _, filter := range []ResampleFilter{Bilinear, Lanczos} {
+		resized := resize(src, 16, 16, filter)
+		for y := 0; y < 16; y++ {
+			prev := grayAt(resized, 0, y)
+			for x := 1; x < 16; x++ {
+				v := grayAt(resized, x, y)
+				if v < prev-1e-9 {
+					t.Fatalf("filter %v: value decreased at x=%d,y=%d (%v -> %v)", filter, x, y, prev, v)
+				}
+				prev = v
+			}
+		}
+		// The gradient runs 0..1 across the source width, so the resized
+		// endpoints should land close to 0 and 1 too.
+		if v := grayAt(resized, 0, 0); v > 0.1 {
+			t.Errorf("filter %v: left edge = %v, want near 0", filter, v)
+		}
+		if v := grayAt(resized, 15, 0); v < 0.9 {
+			t.Errorf("filter %v: right edge = %v, want near 1", filter, v)
+		}
+	}
+}
+
+func TestResizeUpsampleInterpolatesFlatImage(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			src.SetGray(x, y, color.Gray{Y: 128})
+		}
+	}
+	for _, filter := range []ResampleFilter{Bilinear, Lanczos} {
+		resized := resize(src, 32, 32, filter)
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 32; x++ {
+				v := grayAt(resized, x, y)
+				if math.Abs(v-128.0/255.0) > 0.02 {
+					t.Fatalf("filter %v: flat image at (%d,%d) resampled to %v, want ~%v", filter, x, y, v, 128.0/255.0)
+				}
+			}
+		}
+	}
+}
+
+func TestLanczosKernelSupport(t *testing.T) {
+	if v := lanczosKernel(0); v != 1 {
+		t.Errorf("lanczosKernel(0) = %v, want 1", v)
+	}
+	if v := lanczosKernel(3); v != 0 {
+		t.Errorf("lanczosKernel(3) = %v, want 0 (outside support)", v)
+	}
+	if v := lanczosKernel(4); v != 0 {
+		t.Errorf("lanczosKernel(4) = %v, want 0 (outside support)", v)
+	}
+}
+
+func TestBilinearKernelSupport(t *testing.T) {
+	if v := bilinearKernel(0); v != 1 {
+		t.Errorf("bilinearKernel(0) = %v, want 1", v)
+	}
+	if v := bilinearKernel(0.5); v != 0.5 {
+		t.Errorf("bilinearKernel(0.5) = %v, want 0.5", v)
+	}
+	if v := bilinearKernel(1); v != 0 {
+		t.Errorf("bilinearKernel(1) = %v, want 0 (edge of support)", v)
+	}
+}